@@ -0,0 +1,78 @@
+// Package agent interprets free-form chat text into structured task
+// operations using a configurable LLM backend. It is deliberately kept
+// decoupled from the chat package: callers register the task operations
+// they want exposed as tools, and the agent only ever hands back the
+// tool calls the LLM chose to make. Executing those calls against the
+// database is the caller's responsibility.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ToolCall is a single tool invocation the LLM decided to make, with its
+// arguments already decoded from the model's JSON output.
+type ToolCall struct {
+	Name      string
+	Arguments map[string]any
+}
+
+// Tool describes a single operation the agent is allowed to invoke. Schema
+// is the JSON-schema for Arguments, passed to the LLM so it knows how to
+// call the tool; Handler performs the operation and returns text suitable
+// for display back to the user.
+type Tool struct {
+	Name        string
+	Description string
+	Schema      json.RawMessage
+	Handler     func(ctx context.Context, userID string, args map[string]any) (string, error)
+}
+
+// ToolRegistry holds the set of tools an Agent may call. Chat handlers
+// build one per request, wiring each tool's Handler to the same DB
+// functions the regex fast-paths use.
+type ToolRegistry struct {
+	tools map[string]Tool
+	order []string
+}
+
+// NewToolRegistry returns an empty registry ready for Register calls.
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{tools: make(map[string]Tool)}
+}
+
+// Register adds a tool to the registry. Registering a tool with a name
+// that's already present replaces it.
+func (r *ToolRegistry) Register(t Tool) {
+	if _, exists := r.tools[t.Name]; !exists {
+		r.order = append(r.order, t.Name)
+	}
+	r.tools[t.Name] = t
+}
+
+// Tools returns the registered tools in registration order, for building
+// the JSON-schema tool list to send to the LLM.
+func (r *ToolRegistry) Tools() []Tool {
+	out := make([]Tool, 0, len(r.order))
+	for _, name := range r.order {
+		out = append(out, r.tools[name])
+	}
+	return out
+}
+
+// Execute runs the handler for call.Name with call.Arguments.
+func (r *ToolRegistry) Execute(ctx context.Context, userID string, call ToolCall) (string, error) {
+	tool, ok := r.tools[call.Name]
+	if !ok {
+		return "", fmt.Errorf("agent: unknown tool %q", call.Name)
+	}
+	return tool.Handler(ctx, userID, call.Arguments)
+}
+
+// Agent interprets free-form text into zero or more ToolCalls against a
+// ToolRegistry. Implementations talk to a specific LLM backend.
+type Agent interface {
+	Interpret(ctx context.Context, text string, userID string) ([]ToolCall, error)
+}
@@ -0,0 +1,274 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// provider is the subset of an LLM chat-completions API the agent needs:
+// given a user message and a set of callable tools, return the tool calls
+// the model chose to make.
+type provider interface {
+	complete(ctx context.Context, text string, tools []Tool) ([]ToolCall, error)
+}
+
+// llmAgent is the default Agent implementation. It forwards the raw text
+// and the registry's tool schemas to provider and returns whatever tool
+// calls come back.
+type llmAgent struct {
+	provider provider
+	registry *ToolRegistry
+}
+
+// NewFromEnv builds an Agent backed by the provider named in LLM_PROVIDER
+// ("ollama", "openai", or "anthropic"; defaults to "ollama") using the
+// model named in LLM_MODEL. registry supplies the tool schemas offered to
+// the model on every call.
+func NewFromEnv(registry *ToolRegistry) (Agent, error) {
+	name := os.Getenv("LLM_PROVIDER")
+	if name == "" {
+		name = "ollama"
+	}
+	model := os.Getenv("LLM_MODEL")
+
+	var p provider
+	switch name {
+	case "ollama":
+		host := os.Getenv("OLLAMA_HOST")
+		if host == "" {
+			host = "http://localhost:11434"
+		}
+		if model == "" {
+			model = "llama3.1"
+		}
+		p = &ollamaProvider{host: host, model: model, client: defaultClient()}
+	case "openai":
+		if model == "" {
+			model = "gpt-4o-mini"
+		}
+		p = &openAIProvider{apiKey: os.Getenv("OPENAI_API_KEY"), model: model, client: defaultClient()}
+	case "anthropic":
+		if model == "" {
+			model = "claude-3-5-haiku-latest"
+		}
+		p = &anthropicProvider{apiKey: os.Getenv("ANTHROPIC_API_KEY"), model: model, client: defaultClient()}
+	default:
+		return nil, fmt.Errorf("agent: unknown LLM_PROVIDER %q", name)
+	}
+
+	return &llmAgent{provider: p, registry: registry}, nil
+}
+
+func (a *llmAgent) Interpret(ctx context.Context, text string, userID string) ([]ToolCall, error) {
+	return a.provider.complete(ctx, text, a.registry.Tools())
+}
+
+func defaultClient() *http.Client {
+	return &http.Client{Timeout: 20 * time.Second}
+}
+
+// toolCallResponse is the function-call shape of an Ollama reply, whose
+// arguments arrive already decoded. The OpenAI and Anthropic providers
+// below decode their replies with their own shapes.
+type toolCallResponse struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// ollamaProvider talks to a local Ollama server's /api/chat endpoint.
+type ollamaProvider struct {
+	host   string
+	model  string
+	client *http.Client
+}
+
+func (p *ollamaProvider) complete(ctx context.Context, text string, tools []Tool) ([]ToolCall, error) {
+	body, err := json.Marshal(map[string]any{
+		"model":    p.model,
+		"messages": []map[string]string{{"role": "user", "content": text}},
+		"tools":    toOllamaTools(tools),
+		"stream":   false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.host+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("agent: ollama request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Message struct {
+			ToolCalls []struct {
+				Function toolCallResponse `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("agent: decoding ollama response: %w", err)
+	}
+
+	calls := make([]ToolCall, 0, len(out.Message.ToolCalls))
+	for _, tc := range out.Message.ToolCalls {
+		calls = append(calls, ToolCall{Name: tc.Function.Name, Arguments: tc.Function.Arguments})
+	}
+	return calls, nil
+}
+
+func toOllamaTools(tools []Tool) []map[string]any {
+	out := make([]map[string]any, 0, len(tools))
+	for _, t := range tools {
+		out = append(out, map[string]any{
+			"type": "function",
+			"function": map[string]any{
+				"name":        t.Name,
+				"description": t.Description,
+				"parameters":  json.RawMessage(t.Schema),
+			},
+		})
+	}
+	return out
+}
+
+// openAIProvider talks to the OpenAI chat-completions API.
+type openAIProvider struct {
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+func (p *openAIProvider) complete(ctx context.Context, text string, tools []Tool) ([]ToolCall, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("agent: OPENAI_API_KEY is not set")
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"model":    p.model,
+		"messages": []map[string]string{{"role": "user", "content": text}},
+		"tools":    toOllamaTools(tools), // OpenAI and Ollama share the same function-calling tool shape
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("agent: openai request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Choices []struct {
+			Message struct {
+				ToolCalls []struct {
+					Function struct {
+						Name      string `json:"name"`
+						Arguments string `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("agent: decoding openai response: %w", err)
+	}
+	if len(out.Choices) == 0 {
+		return nil, nil
+	}
+
+	calls := make([]ToolCall, 0, len(out.Choices[0].Message.ToolCalls))
+	for _, tc := range out.Choices[0].Message.ToolCalls {
+		var args map[string]any
+		if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+			return nil, fmt.Errorf("agent: decoding openai tool arguments: %w", err)
+		}
+		calls = append(calls, ToolCall{Name: tc.Function.Name, Arguments: args})
+	}
+	return calls, nil
+}
+
+// anthropicProvider talks to the Anthropic messages API.
+type anthropicProvider struct {
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+func (p *anthropicProvider) complete(ctx context.Context, text string, tools []Tool) ([]ToolCall, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("agent: ANTHROPIC_API_KEY is not set")
+	}
+
+	anthropicTools := make([]map[string]any, 0, len(tools))
+	for _, t := range tools {
+		anthropicTools = append(anthropicTools, map[string]any{
+			"name":         t.Name,
+			"description":  t.Description,
+			"input_schema": json.RawMessage(t.Schema),
+		})
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"model":      p.model,
+		"max_tokens": 1024,
+		"messages":   []map[string]string{{"role": "user", "content": text}},
+		"tools":      anthropicTools,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.anthropic.com/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("agent: anthropic request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Content []struct {
+			Type  string         `json:"type"`
+			Name  string         `json:"name"`
+			Input map[string]any `json:"input"`
+		} `json:"content"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("agent: decoding anthropic response: %w", err)
+	}
+
+	var calls []ToolCall
+	for _, block := range out.Content {
+		if block.Type != "tool_use" {
+			continue
+		}
+		calls = append(calls, ToolCall{Name: block.Name, Arguments: block.Input})
+	}
+	return calls, nil
+}
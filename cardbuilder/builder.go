@@ -0,0 +1,75 @@
+package cardbuilder
+
+// CardBuilder assembles a Card one widget at a time, e.g.:
+//
+//	cardbuilder.NewCardBuilder().
+//		Header("Edit Task #42", "").
+//		Section().TextInput("content", "Content", current).
+//		Section().Button("Save", cardbuilder.Action("submitForm", params)).
+//		Build()
+type CardBuilder struct {
+	card    *Card
+	section *CardSection
+}
+
+// NewCardBuilder returns an empty CardBuilder.
+func NewCardBuilder() *CardBuilder {
+	return &CardBuilder{card: &Card{}}
+}
+
+// Header sets the card's title and, optionally, its subtitle.
+func (b *CardBuilder) Header(title string, subtitle string) *CardBuilder {
+	b.card.Header = &CardHeader{Title: title, Subtitle: subtitle}
+	return b
+}
+
+// Section starts a new section; subsequent widget calls add to it.
+func (b *CardBuilder) Section() *CardBuilder {
+	b.card.Sections = append(b.card.Sections, CardSection{})
+	b.section = &b.card.Sections[len(b.card.Sections)-1]
+	return b
+}
+
+// currentSection returns the section widgets should be appended to,
+// starting one if none has been opened yet.
+func (b *CardBuilder) currentSection() *CardSection {
+	if b.section == nil {
+		b.Section()
+	}
+	return b.section
+}
+
+// TextParagraph adds a text paragraph widget to the current section.
+func (b *CardBuilder) TextParagraph(text string) *CardBuilder {
+	s := b.currentSection()
+	s.Widgets = append(s.Widgets, Widget{TextParagraph: &TextParagraph{Text: text}})
+	return b
+}
+
+// TextInput adds a single-line text input widget, pre-filled with value,
+// to the current section.
+func (b *CardBuilder) TextInput(name string, label string, value string) *CardBuilder {
+	s := b.currentSection()
+	s.Widgets = append(s.Widgets, Widget{TextInput: &TextInput{Name: name, Label: label, Value: value}})
+	return b
+}
+
+// Button adds a button to the current section. Consecutive Button calls
+// within the same section collect into a single ButtonList.
+func (b *CardBuilder) Button(text string, action CardAction) *CardBuilder {
+	s := b.currentSection()
+	button := Button{TextButton: &TextButton{Text: text, OnClick: OnClick{Action: action}}}
+
+	if n := len(s.Widgets); n > 0 && s.Widgets[n-1].ButtonList != nil {
+		s.Widgets[n-1].ButtonList.Buttons = append(s.Widgets[n-1].ButtonList.Buttons, button)
+		return b
+	}
+
+	s.Widgets = append(s.Widgets, Widget{ButtonList: &ButtonList{Buttons: []Button{button}}})
+	return b
+}
+
+// Build returns the assembled Card.
+func (b *CardBuilder) Build() *Card {
+	return b.card
+}
@@ -0,0 +1,85 @@
+// Package cardbuilder models Google Chat's card schema and offers a
+// fluent builder on top of it, so multi-step flows can assemble a card
+// one widget at a time instead of hand-nesting struct literals.
+package cardbuilder
+
+// Card represents a Google Chat card.
+type Card struct {
+	Header   *CardHeader   `json:"header,omitempty"`
+	Sections []CardSection `json:"sections"`
+}
+
+// CardHeader represents a card header.
+type CardHeader struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle,omitempty"`
+}
+
+// CardSection represents a card section.
+type CardSection struct {
+	Widgets []Widget `json:"widgets"`
+}
+
+// Widget represents a card widget. Exactly one field should be set.
+type Widget struct {
+	TextParagraph *TextParagraph `json:"textParagraph,omitempty"`
+	ButtonList    *ButtonList    `json:"buttonList,omitempty"`
+	Divider       *Divider       `json:"divider,omitempty"`
+	TextInput     *TextInput     `json:"textInput,omitempty"`
+}
+
+// TextParagraph represents a text paragraph widget.
+type TextParagraph struct {
+	Text string `json:"text"`
+}
+
+// ButtonList represents a button list widget.
+type ButtonList struct {
+	Buttons []Button `json:"buttons"`
+}
+
+// Button represents a button.
+type Button struct {
+	TextButton *TextButton `json:"textButton,omitempty"`
+}
+
+// TextButton represents a text button.
+type TextButton struct {
+	Text    string  `json:"text"`
+	OnClick OnClick `json:"onClick"`
+}
+
+// OnClick represents a button click action.
+type OnClick struct {
+	Action CardAction `json:"action"`
+}
+
+// CardAction represents an action for card buttons.
+type CardAction struct {
+	ActionMethodName string            `json:"actionMethodName"`
+	Parameters       map[string]string `json:"parameters"`
+}
+
+// Divider represents a divider widget.
+type Divider struct{}
+
+// TextInput represents a single-line text input widget, submitted back to
+// the bot as a formInputs entry keyed by Name when its form is submitted.
+type TextInput struct {
+	Name  string `json:"name"`
+	Label string `json:"label,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// FormInputValue is a submitted form field, matching Google Chat's
+// formInputs wire shape.
+type FormInputValue struct {
+	StringInputs struct {
+		Value []string `json:"value"`
+	} `json:"stringInputs"`
+}
+
+// Action builds a CardAction for a button's onClick handler.
+func Action(actionMethodName string, parameters map[string]string) CardAction {
+	return CardAction{ActionMethodName: actionMethodName, Parameters: parameters}
+}
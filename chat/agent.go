@@ -0,0 +1,157 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	"encore.dev/storage/sqldb"
+
+	"encore.app/agent"
+)
+
+// runAgent interprets text with the configured LLM agent and executes
+// whatever tool calls it returns against the same DB-backed functions the
+// regex fast-paths in HandleChat use, within tx. It's the fallback for
+// free-form messages like "remind me to call Bob tomorrow" that don't
+// match one of the fixed commands.
+func runAgent(ctx context.Context, tx *sqldb.Tx, text string, userID string) (*ChatResponse, error) {
+	registry := buildToolRegistry(tx)
+
+	a, err := agent.NewFromEnv(registry)
+	if err != nil {
+		return nil, fmt.Errorf("agent: %w", err)
+	}
+
+	calls, err := a.Interpret(ctx, text, userID)
+	if err != nil {
+		return nil, fmt.Errorf("agent: interpreting %q: %w", text, err)
+	}
+	if len(calls) == 0 {
+		return &ChatResponse{Text: "🤔 I didn't understand that. Send an empty message to see available commands."}, nil
+	}
+
+	var results []string
+	for _, call := range calls {
+		text, err := registry.Execute(ctx, userID, call)
+		if err != nil {
+			return nil, fmt.Errorf("agent: executing %s: %w", call.Name, err)
+		}
+		results = append(results, text)
+	}
+
+	return &ChatResponse{Text: joinNonEmpty(results)}, nil
+}
+
+// buildToolRegistry exposes addTask, listTasks, markTaskDone, editTask
+// and deleteTask as JSON-schema tools the agent's LLM backend can invoke,
+// wiring each one to the response's plain-text rendering. tx is captured
+// by each handler closure so every tool call runs in the same transaction
+// as the request that invoked the agent.
+func buildToolRegistry(tx *sqldb.Tx) *agent.ToolRegistry {
+	registry := agent.NewToolRegistry()
+
+	registry.Register(agent.Tool{
+		Name:        "addTask",
+		Description: "Add a new task for the user",
+		Schema:      []byte(`{"type":"object","properties":{"content":{"type":"string","description":"the task text"}},"required":["content"]}`),
+		Handler: func(ctx context.Context, userID string, args map[string]any) (string, error) {
+			content, _ := args["content"].(string)
+			resp, err := addTask(ctx, tx, content, userID)
+			return responseText(resp, err)
+		},
+	})
+
+	registry.Register(agent.Tool{
+		Name:        "listTasks",
+		Description: "List all of the user's tasks",
+		Schema:      []byte(`{"type":"object","properties":{}}`),
+		Handler: func(ctx context.Context, userID string, args map[string]any) (string, error) {
+			resp, err := listTasks(ctx, tx, userID, taskFilter{})
+			return responseText(resp, err)
+		},
+	})
+
+	registry.Register(agent.Tool{
+		Name:        "markTaskDone",
+		Description: "Mark a task as done by its ID",
+		Schema:      []byte(`{"type":"object","properties":{"id":{"type":"integer","description":"the task ID"}},"required":["id"]}`),
+		Handler: func(ctx context.Context, userID string, args map[string]any) (string, error) {
+			id, err := intArg(args, "id")
+			if err != nil {
+				return "", err
+			}
+			resp, err := markTaskDone(ctx, tx, id, userID)
+			return responseText(resp, err)
+		},
+	})
+
+	registry.Register(agent.Tool{
+		Name:        "editTask",
+		Description: "Replace the content of an existing task by its ID",
+		Schema:      []byte(`{"type":"object","properties":{"id":{"type":"integer","description":"the task ID"},"content":{"type":"string","description":"the new task text"}},"required":["id","content"]}`),
+		Handler: func(ctx context.Context, userID string, args map[string]any) (string, error) {
+			id, err := intArg(args, "id")
+			if err != nil {
+				return "", err
+			}
+			content, _ := args["content"].(string)
+			resp, err := editTask(ctx, tx, id, content, userID)
+			return responseText(resp, err)
+		},
+	})
+
+	registry.Register(agent.Tool{
+		Name:        "deleteTask",
+		Description: "Delete a task by its ID",
+		Schema:      []byte(`{"type":"object","properties":{"id":{"type":"integer","description":"the task ID"}},"required":["id"]}`),
+		Handler: func(ctx context.Context, userID string, args map[string]any) (string, error) {
+			id, err := intArg(args, "id")
+			if err != nil {
+				return "", err
+			}
+			resp, err := deleteTask(ctx, tx, id, userID)
+			return responseText(resp, err)
+		},
+	})
+
+	return registry
+}
+
+// responseText flattens a *ChatResponse/error pair from a DB-backed
+// function into the plain text a tool handler returns to the agent.
+func responseText(resp *ChatResponse, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// intArg reads a numeric argument out of a decoded JSON tool call, where
+// integers surface as float64.
+func intArg(args map[string]any, key string) (int, error) {
+	v, ok := args[key]
+	if !ok {
+		return 0, fmt.Errorf("missing %q argument", key)
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, fmt.Errorf("%q argument must be a number", key)
+	}
+	return int(f), nil
+}
+
+// joinNonEmpty joins tool results with blank-line separation, skipping
+// empty ones so bulk tool calls don't leave stray gaps in the reply.
+func joinNonEmpty(parts []string) string {
+	var out string
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		if out != "" {
+			out += "\n\n"
+		}
+		out += p
+	}
+	return out
+}
@@ -0,0 +1,257 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"encore.dev/storage/sqldb"
+)
+
+// TaskPatch is a partial update to a task. A nil field means "leave this
+// field unchanged"; callers only need to set the fields they want to
+// change.
+type TaskPatch struct {
+	Content  *string `json:"content,omitempty"`
+	Done     *bool   `json:"done,omitempty"`
+	DueDate  *string `json:"due_date,omitempty"`
+	Priority *int    `json:"priority,omitempty"`
+}
+
+// BulkTaskRequest patches every task in IDs with Patch in a single
+// transaction.
+type BulkTaskRequest struct {
+	IDs   []int     `json:"ids"`
+	Patch TaskPatch `json:"patch"`
+}
+
+// BulkTaskResult reports the outcome of patching a single task ID as part
+// of a bulk request.
+type BulkTaskResult struct {
+	ID      int    `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkTaskResponse is the result of a /tasks/bulk call.
+type BulkTaskResponse struct {
+	Results []BulkTaskResult `json:"results"`
+}
+
+//encore:api public method=PATCH path=/tasks/bulk
+func HandleBulkTasks(ctx context.Context, req *BulkTaskRequest) (*BulkTaskResponse, error) {
+	tx, err := sqldb.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	resp := bulkPatchTasksTx(ctx, tx, req.IDs, req.Patch, "default")
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk update: %w", err)
+	}
+	return resp, nil
+}
+
+// bulkPatchTasksTx applies patch to every task in ids for userID within
+// tx, returning a per-ID result so a partial failure doesn't fail the
+// whole batch. The caller owns tx's lifetime (begin/commit/rollback).
+func bulkPatchTasksTx(ctx context.Context, tx *sqldb.Tx, ids []int, patch TaskPatch, userID string) *BulkTaskResponse {
+	results := make([]BulkTaskResult, 0, len(ids))
+	for _, id := range ids {
+		if err := patchTaskTx(ctx, tx, id, patch, userID); err != nil {
+			results = append(results, BulkTaskResult{ID: id, Success: false, Error: err.Error()})
+			continue
+		}
+		results = append(results, BulkTaskResult{ID: id, Success: true})
+	}
+	return &BulkTaskResponse{Results: results}
+}
+
+// patchTaskTx applies the non-nil fields of patch to task id within tx.
+func patchTaskTx(ctx context.Context, tx *sqldb.Tx, id int, patch TaskPatch, userID string) error {
+	var sets []string
+	var args []any
+	n := 1
+
+	if patch.Content != nil {
+		sets = append(sets, fmt.Sprintf("content = $%d", n))
+		args = append(args, *patch.Content)
+		n++
+	}
+	if patch.Done != nil {
+		sets = append(sets, fmt.Sprintf("done = $%d", n))
+		args = append(args, *patch.Done)
+		n++
+	}
+	if patch.DueDate != nil {
+		sets = append(sets, fmt.Sprintf("due_at = $%d", n))
+		args = append(args, *patch.DueDate)
+		n++
+	}
+	if patch.Priority != nil {
+		sets = append(sets, fmt.Sprintf("priority = $%d", n))
+		args = append(args, *patch.Priority)
+		n++
+	}
+	if len(sets) == 0 {
+		return fmt.Errorf("patch has no fields set")
+	}
+
+	args = append(args, id, userID)
+	query := fmt.Sprintf(
+		"UPDATE tasks SET %s WHERE id = $%d AND user_id = $%d",
+		strings.Join(sets, ", "), n, n+1,
+	)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("task not found or doesn't belong to you")
+	}
+	return nil
+}
+
+// bulkDone marks every task in ids as done for userID within tx and
+// renders a consolidated card summarizing which IDs succeeded.
+func bulkDone(ctx context.Context, tx *sqldb.Tx, ids []int, userID string) (*ChatResponse, error) {
+	done := true
+	resp := bulkPatchTasksTx(ctx, tx, ids, TaskPatch{Done: &done}, userID)
+	return bulkResultCard("✅ Bulk done", resp.Results), nil
+}
+
+// bulkDelete deletes every task in ids for userID within tx and renders a
+// consolidated card summarizing which IDs succeeded.
+func bulkDelete(ctx context.Context, tx *sqldb.Tx, ids []int, userID string) (*ChatResponse, error) {
+	results := make([]BulkTaskResult, 0, len(ids))
+	for _, id := range ids {
+		result, err := tx.Exec(ctx, `DELETE FROM tasks WHERE id = $1 AND user_id = $2`, id, userID)
+		if err != nil {
+			results = append(results, BulkTaskResult{ID: id, Success: false, Error: err.Error()})
+			continue
+		}
+		if result.RowsAffected() == 0 {
+			results = append(results, BulkTaskResult{ID: id, Success: false, Error: "task not found or doesn't belong to you"})
+			continue
+		}
+		results = append(results, BulkTaskResult{ID: id, Success: true})
+	}
+
+	return bulkResultCard("🗑️ Bulk delete", results), nil
+}
+
+// bulkAdd adds one task per entry in contents within tx and renders a
+// consolidated card summarizing the new IDs.
+func bulkAdd(ctx context.Context, tx *sqldb.Tx, contents []string, userID string) (*ChatResponse, error) {
+	var lines []string
+	for _, content := range contents {
+		plain, tokens := parseGTDTokens(content)
+
+		var id int
+		err := tx.QueryRow(ctx, `
+			INSERT INTO tasks (content, user_id, context, priority, due_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`, plain, userID, nullString(tokens.Context), tokens.Priority, tokens.DueAt).Scan(&id)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("❌ %q: %s", content, err))
+			continue
+		}
+
+		if err := saveLabelsTx(ctx, tx, id, tokens.Projects); err != nil {
+			lines = append(lines, fmt.Sprintf("❌ %q: %s", content, err))
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("✅ #%d: %s%s", id, priorityEmoji(tokens.Priority), plain))
+	}
+
+	return &ChatResponse{Cards: []Card{{
+		Header:   &CardHeader{Title: "✅ Bulk add"},
+		Sections: []CardSection{{Widgets: []Widget{{TextParagraph: &TextParagraph{Text: strings.Join(lines, "\n")}}}}},
+	}}}, nil
+}
+
+// bulkResultCard renders a per-ID success/failure summary as a single
+// card so fanned-out operations don't require multiple chat messages.
+func bulkResultCard(title string, results []BulkTaskResult) *ChatResponse {
+	lines := make([]string, 0, len(results))
+	for _, r := range results {
+		if r.Success {
+			lines = append(lines, fmt.Sprintf("✅ #%d", r.ID))
+		} else {
+			lines = append(lines, fmt.Sprintf("❌ #%d: %s", r.ID, r.Error))
+		}
+	}
+
+	return &ChatResponse{Cards: []Card{{
+		Header:   &CardHeader{Title: title},
+		Sections: []CardSection{{Widgets: []Widget{{TextParagraph: &TextParagraph{Text: strings.Join(lines, "\n")}}}}},
+	}}}
+}
+
+// parseIDList parses a comma-separated list of IDs and ID ranges, e.g.
+// "1,2,3" or "4-7" or "1,4-7,9", into a sorted, deduplicated slice.
+func parseIDList(s string) ([]int, error) {
+	seen := make(map[int]bool)
+	var ids []int
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if lo, hi, ok := strings.Cut(part, "-"); ok {
+			start, err := strconv.Atoi(strings.TrimSpace(lo))
+			if err != nil {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+			end, err := strconv.Atoi(strings.TrimSpace(hi))
+			if err != nil {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+			if end < start {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+			for id := start; id <= end; id++ {
+				if !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
+			continue
+		}
+
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task ID %q", part)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// parseMultiAdd splits a `add "a"; "b"; "c"` command body into individual
+// quoted task contents.
+func parseMultiAdd(body string) []string {
+	var out []string
+	for _, part := range strings.Split(body, ";") {
+		part = strings.TrimSpace(part)
+		part = strings.Trim(part, `"`)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
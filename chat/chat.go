@@ -6,12 +6,21 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"encore.dev/storage/sqldb"
+
+	"encore.app/cardbuilder"
+	"encore.app/idempotency"
+	"encore.app/scheduler"
 )
 
 // ChatRequest represents the incoming Google Chat webhook request
 type ChatRequest struct {
+	// IdempotencyKey dedupes retried webhook deliveries; falls back to
+	// EventID below when the caller doesn't set it explicitly.
+	IdempotencyKey string `header:"Idempotency-Key"`
+
 	Message struct {
 		Text   string `json:"text"`
 		Sender struct {
@@ -19,16 +28,59 @@ type ChatRequest struct {
 			Email string `json:"email"`
 		} `json:"sender"`
 	} `json:"message"`
-	Action *Action `json:"action,omitempty"`
+	// Space identifies the Google Chat space the message came from, e.g.
+	// "spaces/AAAA1234". Used to address reminder pings back to the
+	// right place.
+	Space struct {
+		Name string `json:"name"`
+	} `json:"space"`
+	// EventID is Google Chat's own delivery identifier, used as the
+	// idempotency key when the Idempotency-Key header isn't set.
+	EventID string  `json:"eventId,omitempty"`
+	Action  *Action `json:"action,omitempty"`
+}
+
+// idempotencyKey returns the key a handler should dedupe req on: the
+// explicit header if set, otherwise Google's own eventId.
+func idempotencyKey(req *ChatRequest) string {
+	if req.IdempotencyKey != "" {
+		return req.IdempotencyKey
+	}
+	return req.EventID
+}
+
+// resolveUserID returns the user identifier for req: prefer email,
+// fallback to name, then a shared default.
+func resolveUserID(req *ChatRequest) string {
+	if req.Message.Sender.Email != "" {
+		return req.Message.Sender.Email
+	}
+	if req.Message.Sender.Name != "" {
+		return req.Message.Sender.Name
+	}
+	return "default"
 }
 
-// Action represents a card action (button click)
+// Action represents a card action (button click or form submission)
 type Action struct {
 	ActionMethodName string `json:"actionMethodName"`
 	Parameters       []struct {
 		Key   string `json:"key"`
 		Value string `json:"value"`
 	} `json:"parameters"`
+	// FormInputs carries any textInput widget values when the action was
+	// triggered by submitting a card form.
+	FormInputs map[string]cardbuilder.FormInputValue `json:"formInputs,omitempty"`
+}
+
+// formInput returns the first submitted value for the named form field,
+// or "" if it wasn't present.
+func formInput(action *Action, name string) string {
+	input, ok := action.FormInputs[name]
+	if !ok || len(input.StringInputs.Value) == 0 {
+		return ""
+	}
+	return input.StringInputs.Value[0]
 }
 
 // ChatResponse represents the response to Google Chat webhook
@@ -43,64 +95,22 @@ type ChatResponseV2 struct {
 	Cards []Card `json:"cards,omitempty"`
 }
 
-// Card represents a Google Chat card
-type Card struct {
-	Header   *CardHeader   `json:"header,omitempty"`
-	Sections []CardSection `json:"sections"`
-}
-
-// CardHeader represents a card header
-type CardHeader struct {
-	Title    string `json:"title"`
-	Subtitle string `json:"subtitle,omitempty"`
-}
-
-// CardSection represents a card section
-type CardSection struct {
-	Widgets []Widget `json:"widgets"`
-}
-
-// Widget represents a card widget
-type Widget struct {
-	TextParagraph *TextParagraph `json:"textParagraph,omitempty"`
-	ButtonList    *ButtonList    `json:"buttonList,omitempty"`
-	Divider       *Divider       `json:"divider,omitempty"`
-}
-
-// TextParagraph represents a text paragraph widget
-type TextParagraph struct {
-	Text string `json:"text"`
-}
-
-// ButtonList represents a button list widget
-type ButtonList struct {
-	Buttons []Button `json:"buttons"`
-}
-
-// Button represents a button
-type Button struct {
-	TextButton *TextButton `json:"textButton,omitempty"`
-}
-
-// TextButton represents a text button
-type TextButton struct {
-	Text    string  `json:"text"`
-	OnClick OnClick `json:"onClick"`
-}
-
-// OnClick represents a button click action
-type OnClick struct {
-	Action CardAction `json:"action"`
-}
-
-// CardAction represents an action for card buttons
-type CardAction struct {
-	ActionMethodName string            `json:"actionMethodName"`
-	Parameters       map[string]string `json:"parameters"`
-}
-
-// Divider represents a divider widget
-type Divider struct{}
+// Card types are the cardbuilder package's Google Chat card schema;
+// aliased here so existing chat code didn't need to change package-
+// qualify every reference when the schema moved into its own package.
+type (
+	Card          = cardbuilder.Card
+	CardHeader    = cardbuilder.CardHeader
+	CardSection   = cardbuilder.CardSection
+	Widget        = cardbuilder.Widget
+	TextParagraph = cardbuilder.TextParagraph
+	ButtonList    = cardbuilder.ButtonList
+	Button        = cardbuilder.Button
+	TextButton    = cardbuilder.TextButton
+	OnClick       = cardbuilder.OnClick
+	CardAction    = cardbuilder.CardAction
+	Divider       = cardbuilder.Divider
+)
 
 //encore:api public method=POST path=/chat
 func HandleChat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
@@ -110,34 +120,78 @@ func HandleChat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
 	}
 
 	text := strings.TrimSpace(req.Message.Text)
+	userID := resolveUserID(req)
 
-	// Get user identifier (prefer email, fallback to name, then default)
-	userID := "default"
-	if req.Message.Sender.Email != "" {
-		userID = req.Message.Sender.Email
-	} else if req.Message.Sender.Name != "" {
-		userID = req.Message.Sender.Name
-	}
+	return idempotency.Wrap(ctx, idempotencyKey(req), userID, func(tx *sqldb.Tx) (*ChatResponse, error) {
+		return dispatchChatCommand(ctx, tx, req, text, userID)
+	})
+}
 
+func dispatchChatCommand(ctx context.Context, tx *sqldb.Tx, req *ChatRequest, text string, userID string) (*ChatResponse, error) {
 	// Parse commands
+	recurringAddCmd := regexp.MustCompile(`^add\s+every\s+(\w+)\s+"(.+)"$`)
+	remindCmd := regexp.MustCompile(`^remind\s+(\d+)\s+in\s+(\d+)([smhd])$`)
+	addMultiCmd := regexp.MustCompile(`^add\s+"[^"]*"(?:\s*;\s*"[^"]*")+$`)
 	addCmd := regexp.MustCompile(`^add\s+(.+)$`)
-	listCmd := regexp.MustCompile(`^list$`)
+	listCmd := regexp.MustCompile(`^list(?:\s+(.*))?$`)
 	doneCmd := regexp.MustCompile(`^done\s+(\d+)$`)
+	doneBulkCmd := regexp.MustCompile(`^done\s+([\d,\-\s]+)$`)
+	deleteCmd := regexp.MustCompile(`^delete\s+(\d+)$`)
+	deleteBulkCmd := regexp.MustCompile(`^delete\s+([\d,\-\s]+)$`)
 	editCmd := regexp.MustCompile(`^edit\s+(\d+)\s+(.+)$`)
 	testCmd := regexp.MustCompile(`^test$`)
 
 	switch {
+	case text == "add wizard":
+		response, err := startAddWizard(ctx, tx, userID, req.Space.Name)
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+
+	case recurringAddCmd.MatchString(text):
+		matches := recurringAddCmd.FindStringSubmatch(text)
+		response, err := addRecurringTask(ctx, tx, strings.ToLower(matches[1]), matches[2], userID, req.Space.Name)
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+
+	case remindCmd.MatchString(text):
+		matches := remindCmd.FindStringSubmatch(text)
+		taskID, _ := strconv.Atoi(matches[1])
+		amount, _ := strconv.Atoi(matches[2])
+		d, err := parseShortDuration(amount, matches[3])
+		if err != nil {
+			return &ChatResponse{Text: fmt.Sprintf("❌ %s", err)}, nil
+		}
+		response, err := remindInDuration(ctx, tx, taskID, d, userID, req.Space.Name)
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+
+	case addMultiCmd.MatchString(text):
+		contents := parseMultiAdd(strings.TrimPrefix(text, "add"))
+		response, err := bulkAdd(ctx, tx, contents, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add tasks: %w", err)
+		}
+		return response, nil
+
 	case addCmd.MatchString(text):
 		matches := addCmd.FindStringSubmatch(text)
 		taskContent := strings.TrimSpace(matches[1])
-		response, err := addTask(ctx, taskContent, userID)
+		response, err := addTask(ctx, tx, taskContent, userID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to add task: %w", err)
 		}
 		return response, nil
 
 	case listCmd.MatchString(text):
-		response, err := listTasks(ctx, userID)
+		matches := listCmd.FindStringSubmatch(text)
+		filter := parseListFilter(matches[1])
+		response, err := listTasks(ctx, tx, userID, filter)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list tasks: %w", err)
 		}
@@ -146,17 +200,50 @@ func HandleChat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
 	case doneCmd.MatchString(text):
 		matches := doneCmd.FindStringSubmatch(text)
 		taskID, _ := strconv.Atoi(matches[1])
-		response, err := markTaskDone(ctx, taskID, userID)
+		response, err := markTaskDone(ctx, tx, taskID, userID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to mark task as done: %w", err)
 		}
 		return response, nil
 
+	case doneBulkCmd.MatchString(text):
+		matches := doneBulkCmd.FindStringSubmatch(text)
+		ids, err := parseIDList(matches[1])
+		if err != nil {
+			return &ChatResponse{Text: fmt.Sprintf("❌ %s", err)}, nil
+		}
+		response, err := bulkDone(ctx, tx, ids, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark tasks as done: %w", err)
+		}
+		return response, nil
+
+	case deleteCmd.MatchString(text):
+		matches := deleteCmd.FindStringSubmatch(text)
+		taskID, _ := strconv.Atoi(matches[1])
+		response, err := deleteTask(ctx, tx, taskID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete task: %w", err)
+		}
+		return response, nil
+
+	case deleteBulkCmd.MatchString(text):
+		matches := deleteBulkCmd.FindStringSubmatch(text)
+		ids, err := parseIDList(matches[1])
+		if err != nil {
+			return &ChatResponse{Text: fmt.Sprintf("❌ %s", err)}, nil
+		}
+		response, err := bulkDelete(ctx, tx, ids, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete tasks: %w", err)
+		}
+		return response, nil
+
 	case editCmd.MatchString(text):
 		matches := editCmd.FindStringSubmatch(text)
 		taskID, _ := strconv.Atoi(matches[1])
 		newContent := strings.TrimSpace(matches[2])
-		response, err := editTask(ctx, taskID, newContent, userID)
+		response, err := editTask(ctx, tx, taskID, newContent, userID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to edit task: %w", err)
 		}
@@ -165,13 +252,27 @@ func HandleChat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
 	case testCmd.MatchString(text):
 		return &ChatResponse{Text: "🧪 Test command working! The bot is responding correctly."}, nil
 
-	default:
+	case text == "":
 		return &ChatResponse{Text: `Available commands:
-• add <task> - Add a new task
-• list - List all tasks
+• add <task> - Add a new task. Use @context, #project, !priority (1-3) and ^due (^tomorrow, ^fri, ...) to tag it
+• add "a"; "b"; "c" - Add several tasks at once
+• add every <weekday> "<task>" - Add a task that repeats every week
+• add wizard - Add a task step-by-step via card prompts
+• list [@context] [!priority] - List tasks, grouped by context
 • done <id> - Mark task as done
+• done 1,2,3 / done 4-7 - Mark several tasks as done
+• delete <id> - Delete a task
+• delete 1,2,3 / delete 4-7 - Delete several tasks
 • edit <id> <new content> - Edit a task
-• test - Test bot functionality`}, nil
+• remind <id> in <n><s|m|h|d> - Get a reminder ping later, e.g. remind 5 in 30m
+• test - Test bot functionality
+
+Or just tell me what you want in plain English, e.g. "remind me to call Bob tomorrow".`}, nil
+
+	default:
+		// No fixed command matched; fall back to the LLM agent so users
+		// can phrase requests in plain English.
+		return runAgent(ctx, tx, text, userID)
 	}
 }
 
@@ -185,14 +286,14 @@ func handleCardAction(ctx context.Context, req *ChatRequest) (*ChatResponse, err
 		return &ChatResponse{Text: "❌ Invalid action"}, nil
 	}
 
-	// Get user identifier
-	userID := "default"
-	if req.Message.Sender.Email != "" {
-		userID = req.Message.Sender.Email
-	} else if req.Message.Sender.Name != "" {
-		userID = req.Message.Sender.Name
-	}
+	userID := resolveUserID(req)
+
+	return idempotency.Wrap(ctx, idempotencyKey(req), userID, func(tx *sqldb.Tx) (*ChatResponse, error) {
+		return dispatchCardAction(ctx, tx, req, userID)
+	})
+}
 
+func dispatchCardAction(ctx context.Context, tx *sqldb.Tx, req *ChatRequest, userID string) (*ChatResponse, error) {
 	// Extract parameters
 	params := make(map[string]string)
 	for _, param := range req.Action.Parameters {
@@ -206,7 +307,7 @@ func handleCardAction(ctx context.Context, req *ChatRequest) (*ChatResponse, err
 		if err != nil {
 			return &ChatResponse{Text: "❌ Invalid task ID"}, nil
 		}
-		return markTaskDone(ctx, taskID, userID)
+		return markTaskDone(ctx, tx, taskID, userID)
 
 	case "deleteTask":
 		taskIDStr := params["taskId"]
@@ -214,7 +315,7 @@ func handleCardAction(ctx context.Context, req *ChatRequest) (*ChatResponse, err
 		if err != nil {
 			return &ChatResponse{Text: "❌ Invalid task ID"}, nil
 		}
-		return deleteTask(ctx, taskID, userID)
+		return deleteTask(ctx, tx, taskID, userID)
 
 	case "editTask":
 		taskIDStr := params["taskId"]
@@ -225,24 +326,38 @@ func handleCardAction(ctx context.Context, req *ChatRequest) (*ChatResponse, err
 
 		// If content is provided, update the task
 		if newContent := params["content"]; newContent != "" {
-			return editTask(ctx, taskID, newContent, userID)
+			return editTask(ctx, tx, taskID, newContent, userID)
 		}
 
 		// Otherwise, show the edit form
-		return showEditForm(ctx, taskID, userID)
+		return showEditForm(ctx, tx, taskID, userID)
+
+	case "snooze":
+		taskIDStr := params["taskId"]
+		taskID, err := strconv.Atoi(taskIDStr)
+		if err != nil {
+			return &ChatResponse{Text: "❌ Invalid task ID"}, nil
+		}
+		return remindInDuration(ctx, tx, taskID, 1*time.Hour, userID, req.Space.Name)
+
+	case "submitForm":
+		return dispatchFormSubmission(ctx, tx, req, params, userID)
+
+	case "wizardNext", "wizardFinish":
+		return continueAddWizard(ctx, tx, req, params, userID)
 
 	case "list":
-		return listTasks(ctx, userID)
+		return listTasks(ctx, tx, userID, taskFilter{})
 
 	default:
 		return &ChatResponse{Text: "❌ Unknown action"}, nil
 	}
 }
 
-func showEditForm(ctx context.Context, taskID int, userID string) (*ChatResponse, error) {
+func showEditForm(ctx context.Context, tx *sqldb.Tx, taskID int, userID string) (*ChatResponse, error) {
 	// Get the current task content
 	var content string
-	err := sqldb.QueryRow(ctx, `
+	err := tx.QueryRow(ctx, `
 		SELECT content FROM tasks WHERE id = $1 AND user_id = $2
 	`, taskID, userID).Scan(&content)
 
@@ -250,44 +365,72 @@ func showEditForm(ctx context.Context, taskID int, userID string) (*ChatResponse
 		return &ChatResponse{Text: fmt.Sprintf("❌ Task with ID %d not found or doesn't belong to you", taskID)}, nil
 	}
 
-	return &ChatResponse{Text: fmt.Sprintf("✏️ Edit Task #%d\nCurrent content: %s\n\nTo edit this task, use the command:\nedit %d <new content>", taskID, content, taskID)}, nil
+	card := cardbuilder.NewCardBuilder().
+		Header(fmt.Sprintf("✏️ Edit Task #%d", taskID), "").
+		Section().TextInput("content", "Content", content).
+		Section().
+		Button("Save", cardbuilder.Action("submitForm", map[string]string{"dialogId": "editTask", "taskId": strconv.Itoa(taskID)})).
+		Button("Snooze 1h", cardbuilder.Action("snooze", map[string]string{"taskId": strconv.Itoa(taskID)})).
+		Build()
+
+	return &ChatResponse{Cards: []Card{*card}}, nil
 }
 
-func addTask(ctx context.Context, content string, userID string) (*ChatResponse, error) {
+func addTask(ctx context.Context, tx *sqldb.Tx, content string, userID string) (*ChatResponse, error) {
+	plain, tokens := parseGTDTokens(content)
+
 	var id int
-	err := sqldb.QueryRow(ctx, `
-		INSERT INTO tasks (content, user_id) 
-		VALUES ($1, $2) 
+	err := tx.QueryRow(ctx, `
+		INSERT INTO tasks (content, user_id, context, priority, due_at)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id
-	`, content, userID).Scan(&id)
-
+	`, plain, userID, nullString(tokens.Context), tokens.Priority, tokens.DueAt).Scan(&id)
 	if err != nil {
 		return nil, err
 	}
 
-	// Return a simple text response for now
-	return &ChatResponse{Text: fmt.Sprintf("✅ Task added with ID: %d\nContent: %s", id, content)}, nil
+	if err := saveLabelsTx(ctx, tx, id, tokens.Projects); err != nil {
+		return nil, err
+	}
+
+	return &ChatResponse{Text: fmt.Sprintf("✅ Task added with ID: %d\nContent: %s%s", id, priorityEmoji(tokens.Priority), plain)}, nil
 }
 
-func listTasks(ctx context.Context, userID string) (*ChatResponse, error) {
-	rows, err := sqldb.Query(ctx, `
-		SELECT id, content, done 
-		FROM tasks 
+func listTasks(ctx context.Context, tx *sqldb.Tx, userID string, filter taskFilter) (*ChatResponse, error) {
+	query := `
+		SELECT id, content, done, context, priority
+		FROM tasks
 		WHERE user_id = $1
-		ORDER BY id
-	`, userID)
+	`
+	args := []any{userID}
+
+	if filter.Context != "" {
+		args = append(args, filter.Context)
+		query += fmt.Sprintf(" AND context = $%d", len(args))
+	}
+	if filter.Priority != nil {
+		args = append(args, *filter.Priority)
+		query += fmt.Sprintf(" AND priority = $%d", len(args))
+	}
+	query += " ORDER BY context NULLS LAST, id"
+
+	rows, err := tx.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tasks []string
+	byContext := make(map[string][]string)
+	var order []string
+	count := 0
 	for rows.Next() {
 		var id int
 		var content string
 		var done bool
+		var taskContext *string
+		var priority *int
 
-		if err := rows.Scan(&id, &content, &done); err != nil {
+		if err := rows.Scan(&id, &content, &done, &taskContext, &priority); err != nil {
 			return nil, err
 		}
 
@@ -296,24 +439,46 @@ func listTasks(ctx context.Context, userID string) (*ChatResponse, error) {
 			status = "✅"
 		}
 
-		tasks = append(tasks, fmt.Sprintf("%d. %s %s", id, status, content))
+		group := "no context"
+		if taskContext != nil {
+			group = "@" + *taskContext
+		}
+		if _, ok := byContext[group]; !ok {
+			order = append(order, group)
+		}
+		byContext[group] = append(byContext[group], fmt.Sprintf("%d. %s %s%s", id, status, priorityEmoji(priority), content))
+		count++
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	if len(tasks) == 0 {
+	if count == 0 {
 		return &ChatResponse{Text: "📝 No tasks found. Use 'add <task>' to create your first task!"}, nil
 	}
 
-	return &ChatResponse{Text: "📋 Your tasks:\n" + strings.Join(tasks, "\n")}, nil
+	var groups []string
+	for _, group := range order {
+		groups = append(groups, fmt.Sprintf("%s\n%s", group, strings.Join(byContext[group], "\n")))
+	}
+
+	return &ChatResponse{Text: "📋 Your tasks:\n\n" + strings.Join(groups, "\n\n")}, nil
+}
+
+// nullString turns an empty string into nil so optional text columns are
+// stored as SQL NULL rather than "".
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
 }
 
-func markTaskDone(ctx context.Context, taskID int, userID string) (*ChatResponse, error) {
-	result, err := sqldb.Exec(ctx, `
-		UPDATE tasks 
-		SET done = true 
+func markTaskDone(ctx context.Context, tx *sqldb.Tx, taskID int, userID string) (*ChatResponse, error) {
+	result, err := tx.Exec(ctx, `
+		UPDATE tasks
+		SET done = true
 		WHERE id = $1 AND user_id = $2
 	`, taskID, userID)
 
@@ -330,9 +495,9 @@ func markTaskDone(ctx context.Context, taskID int, userID string) (*ChatResponse
 	return &ChatResponse{Text: fmt.Sprintf("✅ Task %d marked as done!", taskID)}, nil
 }
 
-func deleteTask(ctx context.Context, taskID int, userID string) (*ChatResponse, error) {
-	result, err := sqldb.Exec(ctx, `
-		DELETE FROM tasks 
+func deleteTask(ctx context.Context, tx *sqldb.Tx, taskID int, userID string) (*ChatResponse, error) {
+	result, err := tx.Exec(ctx, `
+		DELETE FROM tasks
 		WHERE id = $1 AND user_id = $2
 	`, taskID, userID)
 
@@ -346,29 +511,64 @@ func deleteTask(ctx context.Context, taskID int, userID string) (*ChatResponse,
 		return &ChatResponse{Text: fmt.Sprintf("❌ Task with ID %d not found or doesn't belong to you", taskID)}, nil
 	}
 
+	// Otherwise a deleted task's recurring template or pending reminder
+	// stays behind in scheduled_jobs with nothing left to load.
+	if err := scheduler.CancelJobsForTask(ctx, tx, taskID); err != nil {
+		return nil, err
+	}
+
 	return &ChatResponse{Text: fmt.Sprintf("🗑️ Task %d deleted!", taskID)}, nil
 }
 
-func editTask(ctx context.Context, taskID int, newContent string, userID string) (*ChatResponse, error) {
+func editTask(ctx context.Context, tx *sqldb.Tx, taskID int, newContent string, userID string) (*ChatResponse, error) {
 	if newContent == "" {
 		return &ChatResponse{Text: "❌ Task content cannot be empty"}, nil
 	}
 
-	result, err := sqldb.Exec(ctx, `
-		UPDATE tasks 
-		SET content = $1 
-		WHERE id = $2 AND user_id = $3
-	`, newContent, taskID, userID)
+	plain, tokens := parseGTDTokens(newContent)
+
+	// PATCH semantics: content always changes, but context/priority/due_at
+	// only change when the new content actually carried that token — an
+	// `edit 5 fix typo` with no `@`/`!`/`^` tokens must not wipe metadata
+	// set by an earlier `add`/`edit`.
+	sets := []string{"content = $1"}
+	args := []any{plain}
+	if tokens.Context != "" {
+		sets = append(sets, fmt.Sprintf("context = $%d", len(args)+1))
+		args = append(args, tokens.Context)
+	}
+	if tokens.Priority != nil {
+		sets = append(sets, fmt.Sprintf("priority = $%d", len(args)+1))
+		args = append(args, *tokens.Priority)
+	}
+	if tokens.DueAt != nil {
+		sets = append(sets, fmt.Sprintf("due_at = $%d", len(args)+1))
+		args = append(args, *tokens.DueAt)
+	}
+	args = append(args, taskID, userID)
 
+	query := fmt.Sprintf(
+		"UPDATE tasks SET %s WHERE id = $%d AND user_id = $%d",
+		strings.Join(sets, ", "), len(args)-1, len(args),
+	)
+	result, err := tx.Exec(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	rowsAffected := result.RowsAffected()
-
-	if rowsAffected == 0 {
+	if result.RowsAffected() == 0 {
 		return &ChatResponse{Text: fmt.Sprintf("❌ Task with ID %d not found or doesn't belong to you", taskID)}, nil
 	}
 
-	return &ChatResponse{Text: fmt.Sprintf("✏️ Task %d updated to: %s", taskID, newContent)}, nil
+	// Like context/priority/due_at above: only touch projects when the new
+	// content actually carried a `#project` token, so `edit 5 fix typo`
+	// doesn't drop a task's existing projects any more than it drops its
+	// context or due date.
+	if len(tokens.Projects) > 0 {
+		if err := replaceLabelsTx(ctx, tx, taskID, tokens.Projects); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ChatResponse{Text: fmt.Sprintf("✏️ Task %d updated to: %s%s", taskID, priorityEmoji(tokens.Priority), plain)}, nil
 }
@@ -0,0 +1,108 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"encore.dev/storage/sqldb"
+
+	"encore.app/cardbuilder"
+	"encore.app/dialog"
+)
+
+// addWizardDialogID is the dialog_state key for the add-task wizard.
+// There's at most one in flight per (user, space), so a constant ID is
+// enough — the primary key on dialog_state already scopes it per user.
+const addWizardDialogID = "add-wizard"
+
+// startAddWizard begins the add-with-project-and-due wizard: a card
+// asking for the task's content, with its answer routed back through
+// wizardNext on submission.
+func startAddWizard(ctx context.Context, tx *sqldb.Tx, userID string, spaceName string) (*ChatResponse, error) {
+	if _, err := dialog.Start(ctx, tx, userID, spaceName, addWizardDialogID, "content"); err != nil {
+		return nil, fmt.Errorf("failed to start add wizard: %w", err)
+	}
+	return wizardStepCard("📝 New task", "content", "What do you need to do?", "", "Next", "wizardNext"), nil
+}
+
+// continueAddWizard advances the add-task wizard by one step using the
+// form input from the card action that triggered it, persisting progress
+// in dialog_state between each card interaction.
+func continueAddWizard(ctx context.Context, tx *sqldb.Tx, req *ChatRequest, params map[string]string, userID string) (*ChatResponse, error) {
+	state, err := dialog.Get(ctx, tx, userID, req.Space.Name, addWizardDialogID)
+	if errors.Is(err, dialog.ErrNotFound) {
+		return &ChatResponse{Text: "❌ No add wizard in progress. Start one with 'add wizard'."}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load add wizard state: %w", err)
+	}
+
+	switch state.Step {
+	case "content":
+		content := formInput(req.Action, "content")
+		if err := dialog.Advance(ctx, tx, state, "project", map[string]string{"content": content}); err != nil {
+			return nil, err
+		}
+		return wizardStepCard("📝 New task", "project", "Which project is this for? (optional)", "", "Next", "wizardNext"), nil
+
+	case "project":
+		project := formInput(req.Action, "project")
+		if err := dialog.Advance(ctx, tx, state, "due", map[string]string{"project": project}); err != nil {
+			return nil, err
+		}
+		return wizardStepCard("📝 New task", "due", "When's it due? (optional, e.g. tomorrow, fri)", "", "Finish", "wizardFinish"), nil
+
+	case "due":
+		due := formInput(req.Action, "due")
+		if err := dialog.Finish(ctx, tx, userID, req.Space.Name, addWizardDialogID); err != nil {
+			return nil, err
+		}
+		return addTask(ctx, tx, assembleWizardContent(state.Data, due), userID)
+
+	default:
+		return &ChatResponse{Text: fmt.Sprintf("❌ Unknown wizard step %q", state.Step)}, nil
+	}
+}
+
+// assembleWizardContent turns the wizard's collected answers back into
+// the inline-token content addTask already knows how to parse.
+func assembleWizardContent(data map[string]string, due string) string {
+	parts := []string{data["content"]}
+	if project := data["project"]; project != "" {
+		parts = append(parts, "#"+project)
+	}
+	if due != "" {
+		parts = append(parts, "^"+due)
+	}
+	return strings.Join(parts, " ")
+}
+
+// dispatchFormSubmission routes a "submitForm" card action to the right
+// handler based on the dialogId the form's Save button was built with.
+func dispatchFormSubmission(ctx context.Context, tx *sqldb.Tx, req *ChatRequest, params map[string]string, userID string) (*ChatResponse, error) {
+	switch params["dialogId"] {
+	case "editTask":
+		taskID, err := strconv.Atoi(params["taskId"])
+		if err != nil {
+			return &ChatResponse{Text: "❌ Invalid task ID"}, nil
+		}
+		return editTask(ctx, tx, taskID, formInput(req.Action, "content"), userID)
+
+	default:
+		return &ChatResponse{Text: "❌ Unknown form"}, nil
+	}
+}
+
+// wizardStepCard renders a single-question step of a card wizard: one
+// text input plus a button that submits it to the given action.
+func wizardStepCard(title string, fieldName string, label string, value string, buttonText string, actionMethodName string) *ChatResponse {
+	card := cardbuilder.NewCardBuilder().
+		Header(title, "").
+		Section().TextInput(fieldName, label, value).
+		Section().Button(buttonText, cardbuilder.Action(actionMethodName, nil)).
+		Build()
+	return &ChatResponse{Cards: []Card{*card}}
+}
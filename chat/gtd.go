@@ -0,0 +1,171 @@
+package chat
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"encore.dev/storage/sqldb"
+)
+
+// gtdTokens are the inline GTD-style annotations addTask/editTask strip
+// out of task content: @context, #project, !priority and ^due.
+type gtdTokens struct {
+	Context  string
+	Projects []string
+	Priority *int
+	DueAt    *time.Time
+}
+
+var (
+	contextTokenRe  = regexp.MustCompile(`@(\w+)`)
+	projectTokenRe  = regexp.MustCompile(`#(\w+)`)
+	priorityTokenRe = regexp.MustCompile(`!([1-3])`)
+	dueTokenRe      = regexp.MustCompile(`\^(\w+)`)
+)
+
+// parseGTDTokens pulls @context, #project, !priority and ^due tokens out
+// of content and returns the remaining plain text alongside the parsed
+// metadata. Unrecognized ^due values are left in place so they don't
+// silently disappear from the task text.
+func parseGTDTokens(content string) (string, gtdTokens) {
+	var tokens gtdTokens
+
+	if m := contextTokenRe.FindStringSubmatch(content); m != nil {
+		tokens.Context = strings.ToLower(m[1])
+		content = contextTokenRe.ReplaceAllString(content, "")
+	}
+
+	for _, m := range projectTokenRe.FindAllStringSubmatch(content, -1) {
+		tokens.Projects = append(tokens.Projects, strings.ToLower(m[1]))
+	}
+	content = projectTokenRe.ReplaceAllString(content, "")
+
+	if m := priorityTokenRe.FindStringSubmatch(content); m != nil {
+		p, _ := strconv.Atoi(m[1])
+		tokens.Priority = &p
+		content = priorityTokenRe.ReplaceAllString(content, "")
+	}
+
+	if m := dueTokenRe.FindStringSubmatch(content); m != nil {
+		if due, ok := parseDueToken(m[1]); ok {
+			tokens.DueAt = &due
+			content = strings.Replace(content, m[0], "", 1)
+		}
+	}
+
+	return strings.Join(strings.Fields(content), " "), tokens
+}
+
+// parseDueToken resolves a natural-language ^due token ("tomorrow",
+// "today", or a weekday name/abbreviation like "fri") relative to now. It
+// reports ok=false for anything it doesn't recognize.
+func parseDueToken(token string) (time.Time, bool) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch strings.ToLower(token) {
+	case "today":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	}
+
+	weekdays := map[string]time.Weekday{
+		"sun": time.Sunday, "sunday": time.Sunday,
+		"mon": time.Monday, "monday": time.Monday,
+		"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+		"wed": time.Wednesday, "wednesday": time.Wednesday,
+		"thu": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+		"fri": time.Friday, "friday": time.Friday,
+		"sat": time.Saturday, "saturday": time.Saturday,
+	}
+	if wd, ok := weekdays[strings.ToLower(token)]; ok {
+		days := (int(wd) - int(today.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7 // "^fri" on a Friday means next Friday, not today
+		}
+		return today.AddDate(0, 0, days), true
+	}
+
+	return time.Time{}, false
+}
+
+// saveLabelsTx links task id to each of the given project labels,
+// creating any labels that don't exist yet.
+func saveLabelsTx(ctx context.Context, tx *sqldb.Tx, taskID int, projects []string) error {
+	for _, name := range projects {
+		var labelID int
+		err := tx.QueryRow(ctx, `
+			INSERT INTO labels (name) VALUES ($1)
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id
+		`, name).Scan(&labelID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO task_labels (task_id, label_id) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, taskID, labelID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceLabelsTx replaces task id's project labels with the given set,
+// dropping any links not in projects. Unlike saveLabelsTx, which only ever
+// adds, this is what editTask needs when the new content actually carries
+// `#project` tokens — callers must only invoke it in that case, the same
+// way editTask only touches context/priority/due_at when their tokens are
+// present, so an edit with no `#project` at all doesn't wipe a task's
+// existing projects.
+func replaceLabelsTx(ctx context.Context, tx *sqldb.Tx, taskID int, projects []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM task_labels WHERE task_id = $1`, taskID); err != nil {
+		return err
+	}
+	return saveLabelsTx(ctx, tx, taskID, projects)
+}
+
+// taskFilter holds the @context/!priority filters parsed out of a `list`
+// command, e.g. `list @work !1`.
+type taskFilter struct {
+	Context  string
+	Priority *int
+}
+
+// parseListFilter extracts @context and !priority tokens from the
+// arguments following `list`.
+func parseListFilter(args string) taskFilter {
+	var f taskFilter
+	if m := contextTokenRe.FindStringSubmatch(args); m != nil {
+		f.Context = strings.ToLower(m[1])
+	}
+	if m := priorityTokenRe.FindStringSubmatch(args); m != nil {
+		p, _ := strconv.Atoi(m[1])
+		f.Priority = &p
+	}
+	return f
+}
+
+// priorityEmoji color-codes a task's priority for display in chat, with
+// no emoji for unset priorities.
+func priorityEmoji(priority *int) string {
+	if priority == nil {
+		return ""
+	}
+	switch *priority {
+	case 1:
+		return "🔴 "
+	case 2:
+		return "🟡 "
+	case 3:
+		return "🟢 "
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,71 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"encore.dev/storage/sqldb"
+
+	"encore.app/scheduler"
+)
+
+// addRecurringTask creates the first occurrence of a recurring task (e.g.
+// from `add every monday "standup"`) and schedules the scheduler package
+// to re-create it every time weekday comes around, within tx.
+func addRecurringTask(ctx context.Context, tx *sqldb.Tx, weekday string, content string, userID string, spaceName string) (*ChatResponse, error) {
+	plain, tokens := parseGTDTokens(content)
+
+	var id int
+	err := tx.QueryRow(ctx, `
+		INSERT INTO tasks (content, user_id, context, priority)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, plain, userID, nullString(tokens.Context), tokens.Priority).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recurring task: %w", err)
+	}
+
+	if _, err := scheduler.EnqueueRecurring(ctx, tx, userID, id, weekday, spaceName); err != nil {
+		return nil, fmt.Errorf("failed to schedule recurring task: %w", err)
+	}
+
+	return &ChatResponse{Text: fmt.Sprintf("🔁 Task #%d will repeat every %s: %s", id, weekday, plain)}, nil
+}
+
+// parseShortDuration turns a `remind 5 in 30m`-style amount/unit pair into
+// a time.Duration. unit is one of "s", "m", "h", "d".
+func parseShortDuration(amount int, unit string) (time.Duration, error) {
+	switch unit {
+	case "s":
+		return time.Duration(amount) * time.Second, nil
+	case "m":
+		return time.Duration(amount) * time.Minute, nil
+	case "h":
+		return time.Duration(amount) * time.Hour, nil
+	case "d":
+		return time.Duration(amount) * 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("unrecognized duration unit %q", unit)
+	}
+}
+
+// remindInDuration schedules a one-shot reminder ping for taskID after d,
+// posted back to spaceName when due, within tx.
+func remindInDuration(ctx context.Context, tx *sqldb.Tx, taskID int, d time.Duration, userID string, spaceName string) (*ChatResponse, error) {
+	var exists bool
+	if err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND user_id = $2)
+	`, taskID, userID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up task %d: %w", taskID, err)
+	}
+	if !exists {
+		return &ChatResponse{Text: fmt.Sprintf("❌ Task with ID %d not found or doesn't belong to you", taskID)}, nil
+	}
+
+	runAt := time.Now().Add(d)
+	if _, err := scheduler.EnqueueReminder(ctx, tx, userID, taskID, runAt, spaceName); err != nil {
+		return nil, fmt.Errorf("failed to schedule reminder: %w", err)
+	}
+	return &ChatResponse{Text: fmt.Sprintf("⏰ I'll remind you about #%d at %s", taskID, runAt.Format(time.Kitchen))}, nil
+}
@@ -0,0 +1,100 @@
+// Package dialog persists partial input for multi-step card flows (e.g.
+// add-with-project-and-due, bulk-edit-selector) across separate card
+// interactions, keyed by (user_id, space_name, dialog_id).
+package dialog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"encore.dev/storage/sqldb"
+)
+
+// ErrNotFound is returned by Get when no dialog is in progress for the
+// given key.
+var ErrNotFound = errors.New("dialog: not found")
+
+// State is the in-progress state of a multi-step card flow.
+type State struct {
+	UserID    string
+	SpaceName string
+	DialogID  string
+	Step      string
+	Data      map[string]string
+}
+
+// Start begins a new dialog at firstStep, overwriting any dialog already
+// in progress under the same key, within tx.
+func Start(ctx context.Context, tx *sqldb.Tx, userID, spaceName, dialogID, firstStep string) (*State, error) {
+	state := &State{UserID: userID, SpaceName: spaceName, DialogID: dialogID, Step: firstStep, Data: map[string]string{}}
+	if err := save(ctx, tx, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Get loads the in-progress dialog for the given key within tx, or
+// ErrNotFound if none exists.
+func Get(ctx context.Context, tx *sqldb.Tx, userID, spaceName, dialogID string) (*State, error) {
+	var step string
+	var rawData []byte
+	err := tx.QueryRow(ctx, `
+		SELECT step, data FROM dialog_state
+		WHERE user_id = $1 AND space_name = $2 AND dialog_id = $3
+	`, userID, spaceName, dialogID).Scan(&step, &rawData)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dialog: loading %s/%s/%s: %w", userID, spaceName, dialogID, err)
+	}
+
+	data := map[string]string{}
+	if err := json.Unmarshal(rawData, &data); err != nil {
+		return nil, fmt.Errorf("dialog: decoding state for %s/%s/%s: %w", userID, spaceName, dialogID, err)
+	}
+
+	return &State{UserID: userID, SpaceName: spaceName, DialogID: dialogID, Step: step, Data: data}, nil
+}
+
+// Advance merges newData into the dialog's collected data, moves it to
+// nextStep, and persists the result within tx.
+func Advance(ctx context.Context, tx *sqldb.Tx, state *State, nextStep string, newData map[string]string) error {
+	for k, v := range newData {
+		state.Data[k] = v
+	}
+	state.Step = nextStep
+	return save(ctx, tx, state)
+}
+
+// Finish deletes the dialog within tx, since the flow it tracked has
+// completed (or been abandoned).
+func Finish(ctx context.Context, tx *sqldb.Tx, userID, spaceName, dialogID string) error {
+	_, err := tx.Exec(ctx, `
+		DELETE FROM dialog_state WHERE user_id = $1 AND space_name = $2 AND dialog_id = $3
+	`, userID, spaceName, dialogID)
+	if err != nil {
+		return fmt.Errorf("dialog: finishing %s/%s/%s: %w", userID, spaceName, dialogID, err)
+	}
+	return nil
+}
+
+func save(ctx context.Context, tx *sqldb.Tx, state *State) error {
+	encoded, err := json.Marshal(state.Data)
+	if err != nil {
+		return fmt.Errorf("dialog: encoding state for %s/%s/%s: %w", state.UserID, state.SpaceName, state.DialogID, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO dialog_state (user_id, space_name, dialog_id, step, data, updated_at)
+		VALUES ($1, $2, $3, $4, $5, now())
+		ON CONFLICT (user_id, space_name, dialog_id)
+		DO UPDATE SET step = EXCLUDED.step, data = EXCLUDED.data, updated_at = now()
+	`, state.UserID, state.SpaceName, state.DialogID, state.Step, encoded)
+	if err != nil {
+		return fmt.Errorf("dialog: saving %s/%s/%s: %w", state.UserID, state.SpaceName, state.DialogID, err)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package idempotency
+
+import (
+	"context"
+
+	"encore.dev/cron"
+)
+
+var _ = cron.NewJob("sweep-idempotency-keys", cron.JobConfig{
+	Title:    "Delete expired idempotency keys",
+	Every:    1 * cron.Hour,
+	Endpoint: SweepExpiredKeys,
+})
+
+//encore:api private method=POST path=/idempotency/sweep
+func SweepExpiredKeys(ctx context.Context) error {
+	return Sweep(ctx)
+}
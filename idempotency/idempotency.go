@@ -0,0 +1,150 @@
+// Package idempotency lets a mutating handler cache its response against a
+// client-supplied key, so a retried webhook delivery (Google Chat, or any
+// proxy in front of it) replays the original result instead of repeating
+// the mutation.
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"encore.dev/storage/sqldb"
+)
+
+// window is how long a key is honored for before it's eligible for GC.
+const window = "24 hours"
+
+// Wrap runs fn within a single transaction and caches its result under key
+// for 24h. A second call with the same key (and userID) within that window
+// skips fn and returns the first call's result verbatim. An empty key
+// disables idempotency (fn still runs inside its own transaction) and
+// always runs fn, since not every caller has one to offer.
+//
+// The key is claimed (inserted with a NULL response_json) in the same
+// transaction fn runs its mutation in, and the response is recorded and
+// committed together with that mutation. That way a crash can never land
+// between "mutation committed" and "response recorded": either both commit
+// together, or the whole transaction rolls back and a retry sees no claim
+// at all. Two overlapping retries still can't both miss the cache and both
+// run fn: the loser of the INSERT race either returns the winner's cached
+// response, or, if the winner is still mid-flight, ErrInProgress.
+func Wrap[T any](ctx context.Context, key string, userID string, fn func(tx *sqldb.Tx) (T, error)) (T, error) {
+	var zero T
+
+	tx, err := sqldb.Begin(ctx)
+	if err != nil {
+		return zero, fmt.Errorf("idempotency: begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if key == "" {
+		result, err := fn(tx)
+		if err != nil {
+			return zero, err
+		}
+		if err := tx.Commit(); err != nil {
+			return zero, fmt.Errorf("idempotency: committing: %w", err)
+		}
+		return result, nil
+	}
+
+	claimed, err := claimKeyTx(ctx, tx, key, userID)
+	if err != nil {
+		return zero, err
+	}
+	if !claimed {
+		return waitingResult[T](ctx, key, userID)
+	}
+
+	result, fnErr := fn(tx)
+	if fnErr != nil {
+		// The deferred Rollback undoes both the claim and fn's mutation
+		// together, so a failed run never leaves a stuck pending claim
+		// behind for a retry to collide with.
+		return result, fnErr
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return zero, fmt.Errorf("idempotency: encoding response for %q: %w", key, err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE idempotency_keys SET response_json = $3
+		WHERE key = $1 AND user_id = $2
+	`, key, userID, encoded); err != nil {
+		return zero, fmt.Errorf("idempotency: recording key %q: %w", key, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return zero, fmt.Errorf("idempotency: committing key %q: %w", key, err)
+	}
+
+	return result, nil
+}
+
+// claimKeyTx tries to atomically insert a pending (response_json NULL) row
+// for key within tx, taking over an existing row that is older than the
+// window and not yet swept. It reports whether this call won the claim; a
+// loss means either another call already finished (a cached response is
+// ready) or is still running fn (response_json is still NULL).
+func claimKeyTx(ctx context.Context, tx *sqldb.Tx, key, userID string) (bool, error) {
+	result, err := tx.Exec(ctx, `
+		INSERT INTO idempotency_keys (key, user_id, response_json)
+		VALUES ($1, $2, NULL)
+		ON CONFLICT (key, user_id) DO UPDATE
+			SET response_json = NULL, created_at = now()
+			WHERE idempotency_keys.created_at <= now() - interval '`+window+`'
+	`, key, userID)
+	if err != nil {
+		return false, fmt.Errorf("idempotency: claiming key %q: %w", key, err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// ErrInProgress is returned by Wrap when another call with the same key is
+// still running fn, so there's no cached response to replay yet.
+var ErrInProgress = errors.New("idempotency: request with this key is already in progress")
+
+// waitingResult handles the losing side of claimKey's race: it returns the
+// winner's cached response if it's landed, or ErrInProgress if the winner
+// is still mid-flight.
+func waitingResult[T any](ctx context.Context, key, userID string) (T, error) {
+	var zero T
+	var cached []byte
+	err := sqldb.QueryRow(ctx, `
+		SELECT response_json FROM idempotency_keys
+		WHERE key = $1 AND user_id = $2 AND created_at > now() - interval '`+window+`'
+	`, key, userID).Scan(&cached)
+	switch {
+	case errors.Is(err, sqldb.ErrNoRows):
+		// The claim expired (or was rolled back) between our failed insert
+		// and this lookup, so there is no response to replay; report it as
+		// in progress, and a retry will claim the key afresh.
+		return zero, ErrInProgress
+	case err != nil:
+		return zero, fmt.Errorf("idempotency: checking key %q: %w", key, err)
+	case cached == nil:
+		return zero, ErrInProgress
+	}
+
+	var result T
+	if err := json.Unmarshal(cached, &result); err != nil {
+		return zero, fmt.Errorf("idempotency: decoding cached response for %q: %w", key, err)
+	}
+	return result, nil
+}
+
+// Sweep deletes keys older than the idempotency window. Intended to be
+// called periodically (e.g. from a cron job) so the table doesn't grow
+// unbounded.
+func Sweep(ctx context.Context) error {
+	_, err := sqldb.Exec(ctx, `
+		DELETE FROM idempotency_keys WHERE created_at <= now() - interval '`+window+`'
+	`)
+	if err != nil {
+		return fmt.Errorf("idempotency: sweeping expired keys: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+// Package scheduler runs recurring tasks and one-shot reminders. Jobs are
+// persisted in scheduled_jobs and claimed by a cron-triggered sweep, so a
+// reminder or a recurring task survives process restarts the same way the
+// tasks it operates on do.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"encore.dev/cron"
+	"encore.dev/storage/sqldb"
+)
+
+// Kind distinguishes a recurring task template from a one-shot reminder.
+type Kind string
+
+const (
+	KindRecurring Kind = "recurring"
+	KindReminder  Kind = "reminder"
+)
+
+// Job is a row in scheduled_jobs: either "re-insert a copy of TaskID every
+// CronSpec" (KindRecurring) or "ping SpaceName about TaskID at NextRunAt"
+// (KindReminder).
+type Job struct {
+	ID        int
+	UserID    string
+	TaskID    int
+	Kind      Kind
+	CronSpec  string
+	SpaceName string
+	NextRunAt time.Time
+}
+
+// EnqueueRecurring schedules taskID to be re-created (as a fresh task with
+// the same content) every time weekday next occurs, starting with the
+// first occurrence after now. tx is the caller's mutation transaction, so
+// the job and whatever created taskID commit (or roll back) together.
+func EnqueueRecurring(ctx context.Context, tx *sqldb.Tx, userID string, taskID int, weekday string, spaceName string) (*Job, error) {
+	next, err := nextWeekday(weekday, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return insertJobTx(ctx, tx, userID, taskID, KindRecurring, weekday, spaceName, next)
+}
+
+// EnqueueReminder schedules a one-shot reminder ping about taskID at
+// runAt, within the caller's transaction tx.
+func EnqueueReminder(ctx context.Context, tx *sqldb.Tx, userID string, taskID int, runAt time.Time, spaceName string) (*Job, error) {
+	return insertJobTx(ctx, tx, userID, taskID, KindReminder, runAt.Format(time.RFC3339), spaceName, runAt)
+}
+
+// CancelJobsForTask deletes any scheduled jobs (recurring templates or
+// pending reminders) referencing taskID, within tx, so deleting a task
+// doesn't leave behind a job that will fail to load it later.
+func CancelJobsForTask(ctx context.Context, tx *sqldb.Tx, taskID int) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM scheduled_jobs WHERE task_id = $1`, taskID); err != nil {
+		return fmt.Errorf("scheduler: cancelling jobs for task %d: %w", taskID, err)
+	}
+	return nil
+}
+
+func insertJobTx(ctx context.Context, tx *sqldb.Tx, userID string, taskID int, kind Kind, cronOrRunAt string, spaceName string, nextRunAt time.Time) (*Job, error) {
+	job := &Job{UserID: userID, TaskID: taskID, Kind: kind, CronSpec: cronOrRunAt, SpaceName: spaceName, NextRunAt: nextRunAt}
+	err := tx.QueryRow(ctx, `
+		INSERT INTO scheduled_jobs (user_id, task_id, kind, cron_or_runat, space_name, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, userID, taskID, string(kind), cronOrRunAt, spaceName, nextRunAt).Scan(&job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("scheduler: enqueueing job: %w", err)
+	}
+	return job, nil
+}
+
+var _ = cron.NewJob("run-due-scheduled-jobs", cron.JobConfig{
+	Title:    "Run due recurring tasks and reminders",
+	Every:    1 * cron.Minute,
+	Endpoint: RunDueJobs,
+})
+
+//encore:api private method=POST path=/scheduler/run-due
+func RunDueJobs(ctx context.Context) error {
+	// excluded collects jobs that failed this sweep so a single bad job
+	// (one whose error leaves next_run_at untouched) can't get reclaimed
+	// forever and block every other user's due jobs behind it.
+	var excluded []int
+	var firstErr error
+	for {
+		ran, err := runOneDueJob(ctx, excluded)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			excluded = append(excluded, jobIDFromErr(err))
+			continue
+		}
+		if !ran {
+			return firstErr
+		}
+	}
+}
+
+// runOneDueJob claims a single due job, other than those in excluded, with
+// SELECT ... FOR UPDATE SKIP LOCKED so concurrent sweeps never double-run
+// the same job, and reports whether a job was found.
+func runOneDueJob(ctx context.Context, excluded []int) (bool, error) {
+	tx, err := sqldb.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("scheduler: begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	where := "next_run_at <= now()"
+	args := make([]any, 0, len(excluded))
+	for i, id := range excluded {
+		where += fmt.Sprintf(" AND id != $%d", i+1)
+		args = append(args, id)
+	}
+
+	var job Job
+	var kind string
+	err = tx.QueryRow(ctx, `
+		SELECT id, user_id, task_id, kind, cron_or_runat, space_name, next_run_at
+		FROM scheduled_jobs
+		WHERE `+where+`
+		ORDER BY next_run_at
+		FOR UPDATE SKIP LOCKED
+		LIMIT 1
+	`, args...).Scan(&job.ID, &job.UserID, &job.TaskID, &kind, &job.CronSpec, &job.SpaceName, &job.NextRunAt)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("scheduler: claiming due job: %w", err)
+	}
+	job.Kind = Kind(kind)
+
+	switch job.Kind {
+	case KindRecurring:
+		if err := runRecurringTx(ctx, tx, &job); err != nil {
+			return false, jobErr{job.ID, err}
+		}
+	case KindReminder:
+		if err := runReminderTx(ctx, tx, &job); err != nil {
+			return false, jobErr{job.ID, err}
+		}
+	default:
+		return false, jobErr{job.ID, fmt.Errorf("scheduler: unknown job kind %q", job.Kind)}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, jobErr{job.ID, fmt.Errorf("scheduler: committing job %d: %w", job.ID, err)}
+	}
+	return true, nil
+}
+
+// jobErr tags an error with the job that caused it, so RunDueJobs can
+// exclude just that job from the next claim instead of retrying it
+// immediately (and exclusively) forever.
+type jobErr struct {
+	jobID int
+	err   error
+}
+
+func (e jobErr) Error() string { return e.err.Error() }
+func (e jobErr) Unwrap() error { return e.err }
+
+func jobIDFromErr(err error) int {
+	var je jobErr
+	if errors.As(err, &je) {
+		return je.jobID
+	}
+	return 0
+}
+
+// runRecurringTx re-inserts a fresh copy of job.TaskID's task and advances
+// the job to its next occurrence of job.CronSpec.
+func runRecurringTx(ctx context.Context, tx *sqldb.Tx, job *Job) error {
+	var content, userID string
+	var taskContext *string
+	var priority *int
+	err := tx.QueryRow(ctx, `
+		SELECT content, user_id, context, priority FROM tasks WHERE id = $1
+	`, job.TaskID).Scan(&content, &userID, &taskContext, &priority)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		// The template task is gone (e.g. deleted out from under this
+		// recurring job) — there's nothing left to recreate, so drop the
+		// job rather than leave a row that can never load its task.
+		return deleteJobTx(ctx, tx, job.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("scheduler: loading template task %d: %w", job.TaskID, err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO tasks (content, user_id, context, priority)
+		VALUES ($1, $2, $3, $4)
+	`, content, userID, taskContext, priority); err != nil {
+		return fmt.Errorf("scheduler: recreating recurring task %d: %w", job.TaskID, err)
+	}
+
+	// Relative to now, not job.NextRunAt: if the sweep fell behind by more
+	// than a week, RunDueJobs's claim loop would otherwise keep re-claiming
+	// this job (next_run_at still <= now()) and recreate the task once per
+	// missed occurrence in a single sweep.
+	next, err := nextWeekday(job.CronSpec, time.Now())
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE scheduled_jobs SET next_run_at = $1 WHERE id = $2
+	`, next, job.ID); err != nil {
+		return fmt.Errorf("scheduler: rescheduling job %d: %w", job.ID, err)
+	}
+	return nil
+}
+
+// runReminderTx posts a reminder card to the job's space and then deletes
+// the job, since reminders are one-shot.
+func runReminderTx(ctx context.Context, tx *sqldb.Tx, job *Job) error {
+	var content string
+	err := tx.QueryRow(ctx, `SELECT content FROM tasks WHERE id = $1`, job.TaskID).Scan(&content)
+	if errors.Is(err, sqldb.ErrNoRows) {
+		// The reminded task is gone — nothing to ping about, so just drop
+		// the job instead of erroring on every sweep.
+		return deleteJobTx(ctx, tx, job.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("scheduler: loading reminded task %d: %w", job.TaskID, err)
+	}
+
+	if err := postReminder(ctx, job.SpaceName, fmt.Sprintf("⏰ Reminder: #%d %s", job.TaskID, content)); err != nil {
+		return fmt.Errorf("scheduler: posting reminder for job %d: %w", job.ID, err)
+	}
+
+	return deleteJobTx(ctx, tx, job.ID)
+}
+
+// deleteJobTx removes a scheduled job, used both for a reminder's normal
+// one-shot completion and for dropping a job whose task no longer exists.
+func deleteJobTx(ctx context.Context, tx *sqldb.Tx, jobID int) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM scheduled_jobs WHERE id = $1`, jobID); err != nil {
+		return fmt.Errorf("scheduler: clearing job %d: %w", jobID, err)
+	}
+	return nil
+}
+
+// nextWeekday returns the next occurrence of weekday strictly after from.
+func nextWeekday(weekday string, from time.Time) (time.Time, error) {
+	weekdays := map[string]time.Weekday{
+		"sun": time.Sunday, "sunday": time.Sunday,
+		"mon": time.Monday, "monday": time.Monday,
+		"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+		"wed": time.Wednesday, "wednesday": time.Wednesday,
+		"thu": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+		"fri": time.Friday, "friday": time.Friday,
+		"sat": time.Saturday, "saturday": time.Saturday,
+	}
+	wd, ok := weekdays[weekday]
+	if !ok {
+		return time.Time{}, fmt.Errorf("scheduler: unrecognized weekday %q", weekday)
+	}
+
+	today := time.Date(from.Year(), from.Month(), from.Day(), 9, 0, 0, 0, from.Location())
+	days := (int(wd) - int(today.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return today.AddDate(0, 0, days), nil
+}
@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"encore.app/cardbuilder"
+)
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// postReminder posts a plain-text card back to a Google Chat space via its
+// incoming webhook. spaceName is the "spaces/XXXX" value Google Chat sent
+// on the original message; GOOGLE_CHAT_WEBHOOK_BASE_URL is the app's
+// configured webhook base (e.g. "https://chat.googleapis.com/v1") that
+// spaceName gets appended to, with the webhook key/token query params
+// supplied via GOOGLE_CHAT_WEBHOOK_QUERY.
+func postReminder(ctx context.Context, spaceName string, text string) error {
+	base := os.Getenv("GOOGLE_CHAT_WEBHOOK_BASE_URL")
+	if base == "" {
+		return fmt.Errorf("scheduler: GOOGLE_CHAT_WEBHOOK_BASE_URL is not set")
+	}
+
+	url := fmt.Sprintf("%s/%s/messages?%s", base, spaceName, os.Getenv("GOOGLE_CHAT_WEBHOOK_QUERY"))
+
+	card := cardbuilder.NewCardBuilder().TextParagraph(text).Build()
+	body, err := json.Marshal(map[string]any{"cards": []*cardbuilder.Card{card}})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting to space %s: %w", spaceName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("posting to space %s: unexpected status %s", spaceName, resp.Status)
+	}
+	return nil
+}